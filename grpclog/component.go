@@ -22,8 +22,11 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 
 	"google.golang.org/grpc/internal/grpclog"
 )
@@ -35,6 +38,12 @@ type componentData struct {
 	level     int
 }
 
+// envName is the environment variable controlling per-component verbosity
+// and level, e.g. "balancer:INFO_2,WARNING:WARNING". It also accepts
+// glog-style vmodule entries of the form "glob=level" (e.g.
+// "xds/*=INFO_3", "balancer/grpclb/grpclb.go=INFO_5", "**/resolver=WARNING"),
+// matched against the source file of the call site at log time; when
+// several vmodule entries match, the most specific one wins.
 const envName = "GRPC_GO_LOG_LEVEL"
 const sentinel = math.MinInt32
 
@@ -49,22 +58,84 @@ var environmentVars = map[string]*componentData{}
 var prefixVars = map[string]*componentData{}
 var cache = map[string]*componentData{}
 
+// vmoduleRule is a single glob-style rule parsed out of the `file=level`
+// entries in the environment variable (named after glog's -vmodule flag,
+// which this mirrors). pattern is always anchored to match at any directory
+// depth, so "xds/*" and "**/xds/*" are equivalent.
+type vmoduleRule struct {
+	pattern string
+	data    *componentData
+}
+
+var vmoduleRules []*vmoduleRule
+
+// vmoduleCacheKey identifies a single call site: the component doing the
+// logging and the program counter of its caller.
+type vmoduleCacheKey struct {
+	name string
+	pc   uintptr
+}
+
+// vmoduleCache memoizes the vmodule rule (if any) that applies to a given
+// (component, pc) pair, so that matching the glob rules against the call
+// site only happens once per call site rather than on every log call.
+var vmoduleCache sync.Map // map[vmoduleCacheKey]*componentData
+
+// backtraceAtEnvName is the environment variable naming the "file:line"
+// locations (comma-separated, e.g. "clientconn.go:842") that should dump a
+// stack trace every time they log, mirroring glog's -log_backtrace_at.
+const backtraceAtEnvName = "GRPC_GO_LOG_BACKTRACE_AT"
+
+// backtraceAt holds the parsed contents of backtraceAtEnvName, keyed by
+// "basename:line". Left empty (the common case) this costs a single length
+// check per log call instead of the runtime.Caller needed to check it.
+var backtraceAt = map[string]bool{}
+
 // init extracts the component settings from the environment variable.
 func init() {
 	// Pull environment variable data and put in environmentVars and prefixVars
 	v, _ := os.LookupEnv(envName)
-	environmentVars, prefixVars = parseEnvironmentVar(v)
+	environmentVars, prefixVars, vmoduleRules = parseEnvironmentVar(v)
+	if v, ok := os.LookupEnv(backtraceAtEnvName); ok {
+		backtraceAt = parseBacktraceAt(v)
+	}
 }
 
-// parseEnvironmentVar parses an environment variable string and pulls the component settings data.
-func parseEnvironmentVar(envVar string) (map[string]*componentData, map[string]*componentData) {
+// parseBacktraceAt parses a comma-separated list of "file:line" locations.
+func parseBacktraceAt(v string) map[string]bool {
+	m := map[string]bool{}
+	for _, loc := range strings.Split(v, ",") {
+		loc = strings.TrimSpace(loc)
+		if loc != "" {
+			m[loc] = true
+		}
+	}
+	return m
+}
+
+// parseEnvironmentVar parses an environment variable string and pulls the
+// component settings data. Entries of the form "component:level" or
+// "prefix*:level" populate envVars/preVars as before. Any entry containing
+// "=" is treated as a "glob=level" vmodule rule matched against the
+// caller's source file at log time.
+func parseEnvironmentVar(envVar string) (map[string]*componentData, map[string]*componentData, []*vmoduleRule) {
 	envVars := map[string]*componentData{}
 	preVars := map[string]*componentData{}
+	var vRules []*vmoduleRule
 	if len(envVar) == 0 {
-		return envVars, preVars
+		return envVars, preVars, vRules
 	}
 	varList := strings.Split(envVar, ",")
 	for _, varPair := range varList {
+		if idx := strings.Index(varPair, "="); idx >= 0 {
+			pattern, value := varPair[:idx], varPair[idx+1:]
+			if cData, ok := parseVar(pattern, value); ok {
+				vRules = append(vRules, &vmoduleRule{pattern: anchorPattern(pattern), data: &cData})
+			} else {
+				fmt.Fprintf(os.Stderr, "error: could not parse '%v' value '%v', unrecognized value '%v'\n", envName, envVar, value)
+			}
+			continue
+		}
 		varPairList := strings.Split(varPair, ":")
 		if len(varPairList) != 2 {
 			fmt.Fprintf(os.Stderr, "error: could not parse '%v' value '%v', unrecognized key-value pair '%v'\n", envName, envVar, varPair)
@@ -80,7 +151,134 @@ func parseEnvironmentVar(envVar string) (map[string]*componentData, map[string]*
 			fmt.Fprintf(os.Stderr, "error: could not parse '%v' value '%v', unrecognized value '%v'\n", envName, envVar, varPairList[1])
 		}
 	}
-	return envVars, preVars
+	return envVars, preVars, vRules
+}
+
+// anchorPattern normalizes a glob pattern to use "/" separators and anchors
+// it so that it matches regardless of how many leading path components the
+// caller's file has (e.g. "xds/*" behaves like "**/xds/*").
+func anchorPattern(pattern string) string {
+	pattern = filepath.ToSlash(pattern)
+	if strings.HasPrefix(pattern, "**/") {
+		return pattern
+	}
+	return "**/" + pattern
+}
+
+// globMatch reports whether the "/"-separated path matches pattern, where
+// pattern segments are matched with path/filepath.Match and a "**" segment
+// matches zero or more path segments.
+func globMatch(pattern, path string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(path, "/"))
+}
+
+func matchSegments(pattern, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+	if pattern[0] == "**" {
+		if matchSegments(pattern[1:], path) {
+			return true
+		}
+		return len(path) > 0 && matchSegments(pattern, path[1:])
+	}
+	if len(path) == 0 {
+		return false
+	}
+	if ok, err := filepath.Match(pattern[0], path[0]); err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], path[1:])
+}
+
+// specificity scores a glob pattern so that the most specific matching rule
+// can win when several rules match the same call site: literal characters
+// count in favor of a rule, wildcards count against it.
+func specificity(pattern string) int {
+	return len(pattern) - 2*strings.Count(pattern, "*")
+}
+
+// vmoduleOverride returns the componentData of the most specific vmodule
+// rule matching the caller skip frames up the stack, or nil if none
+// applies. Results are cached per (component, pc).
+func (c *componentData) vmoduleOverride(skip int) *componentData {
+	if len(vmoduleRules) == 0 {
+		return nil
+	}
+	pc, file, _, ok := runtime.Caller(skip)
+	if !ok {
+		return nil
+	}
+	key := vmoduleCacheKey{c.name, pc}
+	if v, ok := vmoduleCache.Load(key); ok {
+		if v == nil {
+			return nil
+		}
+		return v.(*componentData)
+	}
+	file = filepath.ToSlash(file)
+	var best *componentData
+	bestScore := -1
+	for _, r := range vmoduleRules {
+		if !globMatch(r.pattern, file) {
+			continue
+		}
+		if score := specificity(r.pattern); score >= bestScore {
+			bestScore = score
+			best = r.data
+		}
+	}
+	vmoduleCache.Store(key, best)
+	return best
+}
+
+// effective returns the componentData to use for a log call, after merging
+// in any vmodule rule that matches the caller skip frames up the stack.
+func (c *componentData) effective(skip int) *componentData {
+	ov := c.vmoduleOverride(skip + 1)
+	if ov == nil {
+		return c
+	}
+	merged := *c
+	merged.apply(ov)
+	return &merged
+}
+
+// maybeAppendBacktrace checks whether the call site skip frames up the
+// stack matches a GRPC_GO_LOG_BACKTRACE_AT location and, if so, appends a
+// formatted goroutine stack trace to args. When backtraceAt is empty (the
+// common case) this skips the runtime.Caller/Callers work entirely.
+func maybeAppendBacktrace(skip int, args []interface{}) []interface{} {
+	if len(backtraceAt) == 0 {
+		return args
+	}
+	_, file, line, ok := runtime.Caller(skip)
+	if !ok {
+		return args
+	}
+	if !backtraceAt[fmt.Sprintf("%s:%d", filepath.Base(file), line)] {
+		return args
+	}
+	return append(args, "\n"+formatStack(skip+1))
+}
+
+// formatStack renders the current goroutine's stack, starting skip frames
+// up, using a small fixed-size buffer so the common (disabled) path above
+// never pays for this allocation.
+func formatStack(skip int) string {
+	var pcs [32]uintptr
+	n := runtime.Callers(skip+1, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	var sb strings.Builder
+	sb.WriteString("goroutine backtrace:\n")
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&sb, "\t%s\n\t\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return sb.String()
 }
 
 // apply applies the parameter componentData to the receiver componentData.
@@ -132,23 +330,26 @@ func getPrefix(s string) (string, bool) {
 }
 
 func (c *componentData) InfoDepth(depth int, args ...interface{}) {
-	if c.level > levelInfo {
+	if c.effective(depth + 2).level > levelInfo {
 		return
 	}
 	args = append([]interface{}{"[" + string(c.name) + "]"}, args...)
+	args = maybeAppendBacktrace(depth+2, args)
 	grpclog.InfoDepth(depth, args...)
 }
 
 func (c *componentData) WarningDepth(depth int, args ...interface{}) {
-	if c.level > levelWarning {
+	if c.effective(depth + 2).level > levelWarning {
 		return
 	}
 	args = append([]interface{}{"[" + string(c.name) + "]"}, args...)
+	args = maybeAppendBacktrace(depth+2, args)
 	grpclog.WarningDepth(depth, args...)
 }
 
 func (c *componentData) ErrorDepth(depth int, args ...interface{}) {
 	args = append([]interface{}{"[" + string(c.name) + "]"}, args...)
+	args = maybeAppendBacktrace(depth+2, args)
 	grpclog.ErrorDepth(depth, args...)
 }
 
@@ -157,65 +358,79 @@ func (c *componentData) FatalDepth(depth int, args ...interface{}) {
 	grpclog.FatalDepth(depth, args...)
 }
 
+// Info, Warning, Error, Fatal and their ln/f variants are all one frame
+// above their *Depth counterpart, so each calls it with depth 1, not 0 -
+// otherwise vmoduleOverride and maybeAppendBacktrace (which resolve the
+// call site using that depth) would resolve to this wrapper's own frame
+// in component.go instead of the real caller's.
 func (c *componentData) Info(args ...interface{}) {
-	c.InfoDepth(0, args...)
+	c.InfoDepth(1, args...)
 }
 
 func (c *componentData) Warning(args ...interface{}) {
-	c.WarningDepth(0, args...)
+	c.WarningDepth(1, args...)
 }
 
 func (c *componentData) Error(args ...interface{}) {
-	c.ErrorDepth(0, args...)
+	c.ErrorDepth(1, args...)
 }
 
 func (c *componentData) Fatal(args ...interface{}) {
-	c.FatalDepth(0, args...)
+	c.FatalDepth(1, args...)
 }
 
 func (c *componentData) Infof(format string, args ...interface{}) {
-	c.InfoDepth(0, fmt.Sprintf(format, args...))
+	c.InfoDepth(1, fmt.Sprintf(format, args...))
 }
 
 func (c *componentData) Warningf(format string, args ...interface{}) {
-	c.WarningDepth(0, fmt.Sprintf(format, args...))
+	c.WarningDepth(1, fmt.Sprintf(format, args...))
 }
 
 func (c *componentData) Errorf(format string, args ...interface{}) {
-	c.ErrorDepth(0, fmt.Sprintf(format, args...))
+	c.ErrorDepth(1, fmt.Sprintf(format, args...))
 }
 
 func (c *componentData) Fatalf(format string, args ...interface{}) {
-	c.FatalDepth(0, fmt.Sprintf(format, args...))
+	c.FatalDepth(1, fmt.Sprintf(format, args...))
 }
 
+// Infoln and its Warning/Error/Fatal siblings call their *Depth method
+// directly, rather than through Info etc., so as not to introduce a second
+// wrapper frame on top of the one already accounted for above.
 func (c *componentData) Infoln(args ...interface{}) {
-	c.Info(args...)
+	c.InfoDepth(1, args...)
 }
 
 func (c *componentData) Warningln(args ...interface{}) {
-	c.Warning(args...)
+	c.WarningDepth(1, args...)
 }
 
 func (c *componentData) Errorln(args ...interface{}) {
-	c.Error(args...)
+	c.ErrorDepth(1, args...)
 }
 
 func (c *componentData) Fatalln(args ...interface{}) {
-	c.Fatal(args...)
+	c.FatalDepth(1, args...)
 }
 
 func (c *componentData) V(l int) bool {
-	if c.verbosity == sentinel {
+	eff := c.effective(2)
+	if eff.verbosity == sentinel {
 		return grpclog.Logger.V(l)
 	}
-	return c.verbosity >= l
+	return eff.verbosity >= l
 }
 
 // Component creates a new component and returns it for logging. If a component
 // with the name already exists, nothing will be created and it will be
 // returned. SetLoggerV2 will panic if it is called with a logger created by
 // Component.
+//
+// The returned value also implements StructuredLoggerV2 and
+// RateLimitedLogger; callers that need those, such as internal/channelz,
+// type-assert to reach them rather than widening this return type, so as
+// not to force every caller of Component to take on those methods.
 func Component(componentName string) DepthLoggerV2 {
 	if cData, ok := cache[componentName]; ok {
 		return cData
@@ -19,13 +19,17 @@
 package grpclog
 
 import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sync"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 )
 
 func parseAndCompare(t *testing.T, envVar string, envVars, preVars map[string]*componentData) {
-	envVarsResult, preVarsResult := parseEnvironmentVar(envVar)
+	envVarsResult, preVarsResult, _ := parseEnvironmentVar(envVar)
 	if !cmp.Equal(envVars, envVarsResult, cmp.AllowUnexported(componentData{})) {
 		t.Errorf("Failed to parse environment variable '%v'.\nBegin Diff\n%vEnd Diff\n", envVar, cmp.Diff(&envVars, &envVarsResult, cmp.AllowUnexported(componentData{})))
 	}
@@ -72,3 +76,101 @@ func TestEnvironmentParser(t *testing.T) {
 		})
 	}
 }
+
+func TestVmoduleParser(t *testing.T) {
+	_, _, rules := parseEnvironmentVar("xds/*=INFO_3,balancer/grpclb/grpclb.go=INFO_5,**/resolver=WARNING")
+	if len(rules) != 3 {
+		t.Fatalf("got %v vmodule rules, want 3", len(rules))
+	}
+	want := []string{"**/xds/*", "**/balancer/grpclb/grpclb.go", "**/resolver"}
+	for i, r := range rules {
+		if r.pattern != want[i] {
+			t.Errorf("rules[%v].pattern = %v, want %v", i, r.pattern, want[i])
+		}
+	}
+}
+
+func TestParseBacktraceAt(t *testing.T) {
+	got := parseBacktraceAt("clientconn.go:842, component.go:12,")
+	want := map[string]bool{"clientconn.go:842": true, "component.go:12": true}
+	if !cmp.Equal(got, want) {
+		t.Errorf("parseBacktraceAt() = %v, want %v", got, want)
+	}
+}
+
+func TestMaybeAppendBacktraceNoOpWhenEmpty(t *testing.T) {
+	backtraceAt = map[string]bool{}
+	args := []interface{}{"hello"}
+	got := maybeAppendBacktrace(1, args)
+	if len(got) != 1 {
+		t.Errorf("maybeAppendBacktrace() with empty backtraceAt modified args: %v", got)
+	}
+}
+
+// viaInfoDepth and viaInfo mirror InfoDepth's and Info's own call shape
+// (c.effective(depth+2) with depth=1) so the test below exercises the same
+// two-wrapper-frame chain a real Info() call goes through, without needing
+// to capture actual log output.
+func viaInfoDepth(c *componentData) *componentData {
+	return c.effective(1 + 2)
+}
+
+func viaInfo(c *componentData) *componentData {
+	return viaInfoDepth(c)
+}
+
+func TestEffectiveResolvesThroughConvenienceWrapperFrame(t *testing.T) {
+	origRules, origCache := vmoduleRules, vmoduleCache
+	defer func() { vmoduleRules = origRules; vmoduleCache = origCache }()
+	vmoduleRules = []*vmoduleRule{{pattern: "**/component_test.go", data: &componentData{verbosity: sentinel, level: levelError}}}
+	vmoduleCache = sync.Map{}
+
+	c := &componentData{name: "test", verbosity: sentinel, level: levelInfo}
+	if got := viaInfo(c).level; got != levelError {
+		t.Errorf("effective level resolved through Info's wrapper frame = %v, want %v; the vmodule rule on this call site's own file should still match", got, levelError)
+	}
+}
+
+// viaInfoDepthBacktrace and viaInfoBacktrace mirror InfoDepth's and Info's
+// own call shape (maybeAppendBacktrace(depth+2, args) with depth=1), the
+// same off-by-one this package's vmodule support had: GRPC_GO_LOG_BACKTRACE_AT
+// must resolve the call site through that same two-wrapper-frame chain.
+func viaInfoDepthBacktrace(args []interface{}) []interface{} {
+	return maybeAppendBacktrace(1+2, args)
+}
+
+func viaInfoBacktrace(args []interface{}) []interface{} {
+	return viaInfoDepthBacktrace(args)
+}
+
+func TestMaybeAppendBacktraceResolvesThroughConvenienceWrapperFrame(t *testing.T) {
+	orig := backtraceAt
+	defer func() { backtraceAt = orig }()
+
+	_, file, line, _ := runtime.Caller(0)
+	backtraceAt = map[string]bool{fmt.Sprintf("%s:%d", filepath.Base(file), line+2): true}
+	got := viaInfoBacktrace([]interface{}{"hello"})
+
+	if len(got) != 2 {
+		t.Errorf("maybeAppendBacktrace via Info's wrapper frame = %v, want a stack trace appended", got)
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"**/xds/*", "google.golang.org/grpc/xds/balancer.go", true},
+		{"**/xds/*", "google.golang.org/grpc/xds/internal/balancer.go", false},
+		{"**/balancer/grpclb/grpclb.go", "google.golang.org/grpc/balancer/grpclb/grpclb.go", true},
+		{"**/balancer/grpclb/grpclb.go", "google.golang.org/grpc/balancer/roundrobin/roundrobin.go", false},
+		{"**/resolver", "google.golang.org/grpc/resolver", true},
+		{"**/resolver", "google.golang.org/grpc/resolver/manual.go", false},
+	}
+	for _, tt := range tests {
+		if got := globMatch(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("globMatch(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+/*
+ *
+ * Copyright 2020 gRPC authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package grpclog
+
+import (
+	"io"
+	"log"
+	"os"
+)
+
+// loggerV2 is the default LoggerV2 implementation, writing INFO, WARNING and
+// ERROR severities to their own *log.Logger so each can be independently
+// directed or discarded.
+type loggerV2 struct {
+	infoLog    *log.Logger
+	warningLog *log.Logger
+	errorLog   *log.Logger
+	fatalLog   *log.Logger
+}
+
+// newLoggerV2 creates a loggerV2 that writes to stderr, with all severities
+// enabled.
+func newLoggerV2() LoggerV2 {
+	return newLoggerV2WithWriters(os.Stderr, os.Stderr, os.Stderr)
+}
+
+func newLoggerV2WithWriters(infoW, warningW, errorW io.Writer) LoggerV2 {
+	const logFlags = log.LstdFlags
+	return &loggerV2{
+		infoLog:    log.New(infoW, "INFO: ", logFlags),
+		warningLog: log.New(warningW, "WARNING: ", logFlags),
+		errorLog:   log.New(errorW, "ERROR: ", logFlags),
+		fatalLog:   log.New(io.MultiWriter(errorW, os.Stderr), "FATAL: ", logFlags),
+	}
+}
+
+func (g *loggerV2) Info(args ...interface{}) {
+	g.infoLog.Print(args...)
+}
+
+func (g *loggerV2) Infoln(args ...interface{}) {
+	g.infoLog.Println(args...)
+}
+
+func (g *loggerV2) Infof(format string, args ...interface{}) {
+	g.infoLog.Printf(format, args...)
+}
+
+func (g *loggerV2) Warning(args ...interface{}) {
+	g.warningLog.Print(args...)
+}
+
+func (g *loggerV2) Warningln(args ...interface{}) {
+	g.warningLog.Println(args...)
+}
+
+func (g *loggerV2) Warningf(format string, args ...interface{}) {
+	g.warningLog.Printf(format, args...)
+}
+
+func (g *loggerV2) Error(args ...interface{}) {
+	g.errorLog.Print(args...)
+}
+
+func (g *loggerV2) Errorln(args ...interface{}) {
+	g.errorLog.Println(args...)
+}
+
+func (g *loggerV2) Errorf(format string, args ...interface{}) {
+	g.errorLog.Printf(format, args...)
+}
+
+func (g *loggerV2) Fatal(args ...interface{}) {
+	g.fatalLog.Fatal(args...)
+}
+
+func (g *loggerV2) Fatalln(args ...interface{}) {
+	g.fatalLog.Fatalln(args...)
+}
+
+func (g *loggerV2) Fatalf(format string, args ...interface{}) {
+	g.fatalLog.Fatalf(format, args...)
+}
+
+func (g *loggerV2) V(l int) bool {
+	// The default logger is not verbosity-aware outside of the per-component
+	// settings handled by Component; treat everything as enabled.
+	return true
+}
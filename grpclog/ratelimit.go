@@ -0,0 +1,232 @@
+/*
+ *
+ * Copyright 2020 gRPC authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package grpclog
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxCallSiteCounters bounds the number of call sites tracked by
+// InfoEvery/InfoFirstN/InfoThrottle (and their Warning/Error variants), so a
+// pathological caller (e.g. a program that logs from a very large number of
+// distinct call sites) can't leak memory.
+const maxCallSiteCounters = 4096
+
+// callSiteShards is the number of shards callSiteCounters splits its call
+// sites across, so concurrent logging from different call sites isn't
+// serialized on one mutex. Each shard is bounded to
+// maxCallSiteCounters/callSiteShards entries.
+const callSiteShards = 64
+
+// callSiteState is the per-call-site counter used by the rate-limited
+// logging helpers below.
+type callSiteState struct {
+	count        uint64
+	lastEmitNano int64
+}
+
+// callSiteShard is one bucket of callSiteCounters: a plain map behind its
+// own mutex, with no recency tracking. Eviction is arbitrary rather than
+// LRU, which is a deliberate trade: exact LRU order would need an O(n)
+// touch under the shard's lock on every hit, which is the bottleneck this
+// type exists to avoid. A pathological caller might get evicted sooner
+// than with strict LRU, but it's rare in practice (shards are small) and
+// bounded regardless.
+type callSiteShard struct {
+	mu sync.Mutex
+	m  map[uintptr]*callSiteState
+}
+
+// callSiteCounters is a map from call site (program counter) to its
+// counter state, sharded by pc so that rate-limited logging from distinct
+// call sites doesn't contend on a single global lock.
+type callSiteCounters struct {
+	shards [callSiteShards]*callSiteShard
+}
+
+// newCallSiteCounters constructs a callSiteCounters with all of its shards
+// initialized.
+func newCallSiteCounters() *callSiteCounters {
+	c := &callSiteCounters{}
+	for i := range c.shards {
+		c.shards[i] = &callSiteShard{m: map[uintptr]*callSiteState{}}
+	}
+	return c
+}
+
+var siteCounters = newCallSiteCounters()
+
+func (c *callSiteCounters) shardFor(pc uintptr) *callSiteShard {
+	return c.shards[pc%callSiteShards]
+}
+
+func (c *callSiteCounters) get(pc uintptr) *callSiteState {
+	shard := c.shardFor(pc)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	if s, ok := shard.m[pc]; ok {
+		return s
+	}
+	if len(shard.m) >= maxCallSiteCounters/callSiteShards {
+		for other := range shard.m {
+			delete(shard.m, other)
+			break
+		}
+	}
+	s := &callSiteState{}
+	shard.m[pc] = s
+	return s
+}
+
+// len returns the total number of call sites currently tracked across all
+// shards; it exists for tests.
+func (c *callSiteCounters) len() int {
+	n := 0
+	for _, shard := range c.shards {
+		shard.mu.Lock()
+		n += len(shard.m)
+		shard.mu.Unlock()
+	}
+	return n
+}
+
+// callerPC returns the program counter of the caller skip frames up the
+// stack, used to key the per-call-site counters.
+func callerPC(skip int) uintptr {
+	pc, _, _, _ := runtime.Caller(skip)
+	return pc
+}
+
+// shouldLogEvery reports whether this is the 1st, (n+1)th, (2n+1)th, ...
+// call made from pc.
+func shouldLogEvery(pc uintptr, n int) bool {
+	if n <= 1 {
+		return true
+	}
+	c := atomic.AddUint64(&siteCounters.get(pc).count, 1)
+	return (c-1)%uint64(n) == 0
+}
+
+// shouldLogFirstN reports whether this is among the first n calls made from
+// pc.
+func shouldLogFirstN(pc uintptr, n int) bool {
+	c := atomic.AddUint64(&siteCounters.get(pc).count, 1)
+	return c <= uint64(n)
+}
+
+// shouldLogThrottle reports whether at least d has passed since the last
+// call from pc that returned true.
+func shouldLogThrottle(pc uintptr, d time.Duration) bool {
+	s := siteCounters.get(pc)
+	now := time.Now().UnixNano()
+	for {
+		last := atomic.LoadInt64(&s.lastEmitNano)
+		if now-last < int64(d) {
+			return false
+		}
+		if atomic.CompareAndSwapInt64(&s.lastEmitNano, last, now) {
+			return true
+		}
+	}
+}
+
+// RateLimitedLogger is implemented by loggers returned from Component; it
+// provides rate-limited and deduplicating variants of Info/Warning/Error,
+// each keyed by its own call site. This is the glog "log every N" idiom
+// used heavily to avoid spamming logs from hot paths.
+type RateLimitedLogger interface {
+	// InfoEvery logs at INFO severity on the 1st, (n+1)th, (2n+1)th, ...
+	// call made from this call site.
+	InfoEvery(n int, args ...interface{})
+	// WarningEvery is InfoEvery at WARNING severity.
+	WarningEvery(n int, args ...interface{})
+	// ErrorEvery is InfoEvery at ERROR severity.
+	ErrorEvery(n int, args ...interface{})
+	// InfoFirstN logs at INFO severity for only the first n calls made
+	// from this call site.
+	InfoFirstN(n int, args ...interface{})
+	// WarningFirstN is InfoFirstN at WARNING severity.
+	WarningFirstN(n int, args ...interface{})
+	// ErrorFirstN is InfoFirstN at ERROR severity.
+	ErrorFirstN(n int, args ...interface{})
+	// InfoThrottle logs at INFO severity at most once per d from this call
+	// site.
+	InfoThrottle(d time.Duration, args ...interface{})
+	// WarningThrottle is InfoThrottle at WARNING severity.
+	WarningThrottle(d time.Duration, args ...interface{})
+	// ErrorThrottle is InfoThrottle at ERROR severity.
+	ErrorThrottle(d time.Duration, args ...interface{})
+}
+
+func (c *componentData) InfoEvery(n int, args ...interface{}) {
+	if shouldLogEvery(callerPC(2), n) {
+		c.InfoDepth(1, args...)
+	}
+}
+
+func (c *componentData) WarningEvery(n int, args ...interface{}) {
+	if shouldLogEvery(callerPC(2), n) {
+		c.WarningDepth(1, args...)
+	}
+}
+
+func (c *componentData) ErrorEvery(n int, args ...interface{}) {
+	if shouldLogEvery(callerPC(2), n) {
+		c.ErrorDepth(1, args...)
+	}
+}
+
+func (c *componentData) InfoFirstN(n int, args ...interface{}) {
+	if shouldLogFirstN(callerPC(2), n) {
+		c.InfoDepth(1, args...)
+	}
+}
+
+func (c *componentData) WarningFirstN(n int, args ...interface{}) {
+	if shouldLogFirstN(callerPC(2), n) {
+		c.WarningDepth(1, args...)
+	}
+}
+
+func (c *componentData) ErrorFirstN(n int, args ...interface{}) {
+	if shouldLogFirstN(callerPC(2), n) {
+		c.ErrorDepth(1, args...)
+	}
+}
+
+func (c *componentData) InfoThrottle(d time.Duration, args ...interface{}) {
+	if shouldLogThrottle(callerPC(2), d) {
+		c.InfoDepth(1, args...)
+	}
+}
+
+func (c *componentData) WarningThrottle(d time.Duration, args ...interface{}) {
+	if shouldLogThrottle(callerPC(2), d) {
+		c.WarningDepth(1, args...)
+	}
+}
+
+func (c *componentData) ErrorThrottle(d time.Duration, args ...interface{}) {
+	if shouldLogThrottle(callerPC(2), d) {
+		c.ErrorDepth(1, args...)
+	}
+}
@@ -0,0 +1,68 @@
+/*
+ *
+ * Copyright 2020 gRPC authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package grpclog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShouldLogEvery(t *testing.T) {
+	pc := callerPC(1)
+	var got []bool
+	for i := 0; i < 7; i++ {
+		got = append(got, shouldLogEvery(pc, 3))
+	}
+	want := []bool{true, false, false, true, false, false, true}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("call %v: shouldLogEvery() = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestShouldLogFirstN(t *testing.T) {
+	pc := callerPC(1)
+	for i := 0; i < 5; i++ {
+		want := i < 2
+		if got := shouldLogFirstN(pc, 2); got != want {
+			t.Errorf("call %v: shouldLogFirstN() = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestShouldLogThrottle(t *testing.T) {
+	pc := callerPC(1)
+	if !shouldLogThrottle(pc, time.Hour) {
+		t.Error("first call to shouldLogThrottle() = false, want true")
+	}
+	if shouldLogThrottle(pc, time.Hour) {
+		t.Error("second immediate call to shouldLogThrottle() = true, want false")
+	}
+}
+
+func TestCallSiteCountersBounded(t *testing.T) {
+	c := newCallSiteCounters()
+	for i := uintptr(0); i < maxCallSiteCounters+10; i++ {
+		c.get(i)
+	}
+	if n := c.len(); n > maxCallSiteCounters {
+		t.Errorf("c.len() = %v, want <= %v", n, maxCallSiteCounters)
+	}
+}
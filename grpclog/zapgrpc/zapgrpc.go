@@ -0,0 +1,146 @@
+/*
+ *
+ * Copyright 2020 gRPC authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package zapgrpc defines a logger that adapts zap's Logger to be used with
+// grpclog, preserving structured key/value fields instead of flattening
+// them into a formatted string.
+package zapgrpc // import "google.golang.org/grpc/grpclog/zapgrpc"
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"google.golang.org/grpc/grpclog"
+)
+
+// Logger adapts a *zap.Logger to grpclog.LoggerV2 and
+// grpclog.StructuredLoggerV2.
+type Logger struct {
+	log *zap.Logger
+}
+
+// NewLogger returns a new Logger backed by l. Use grpclog.SetLoggerV2 to
+// install it.
+func NewLogger(l *zap.Logger) *Logger {
+	return &Logger{log: l.WithOptions(zap.AddCallerSkip(1))}
+}
+
+// Info logs args at INFO severity.
+func (l *Logger) Info(args ...interface{}) {
+	l.log.Sugar().Info(args...)
+}
+
+// Infoln logs args at INFO severity.
+func (l *Logger) Infoln(args ...interface{}) {
+	l.log.Sugar().Info(args...)
+}
+
+// Infof logs a formatted message at INFO severity.
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.log.Sugar().Infof(format, args...)
+}
+
+// Warning logs args at WARNING severity.
+func (l *Logger) Warning(args ...interface{}) {
+	l.log.Sugar().Warn(args...)
+}
+
+// Warningln logs args at WARNING severity.
+func (l *Logger) Warningln(args ...interface{}) {
+	l.log.Sugar().Warn(args...)
+}
+
+// Warningf logs a formatted message at WARNING severity.
+func (l *Logger) Warningf(format string, args ...interface{}) {
+	l.log.Sugar().Warnf(format, args...)
+}
+
+// Error logs args at ERROR severity.
+func (l *Logger) Error(args ...interface{}) {
+	l.log.Sugar().Error(args...)
+}
+
+// Errorln logs args at ERROR severity.
+func (l *Logger) Errorln(args ...interface{}) {
+	l.log.Sugar().Error(args...)
+}
+
+// Errorf logs a formatted message at ERROR severity.
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.log.Sugar().Errorf(format, args...)
+}
+
+// Fatal logs args at FATAL severity, then calls os.Exit(1).
+func (l *Logger) Fatal(args ...interface{}) {
+	l.log.Sugar().Fatal(args...)
+}
+
+// Fatalln logs args at FATAL severity, then calls os.Exit(1).
+func (l *Logger) Fatalln(args ...interface{}) {
+	l.log.Sugar().Fatal(args...)
+}
+
+// Fatalf logs a formatted message at FATAL severity, then calls os.Exit(1).
+func (l *Logger) Fatalf(format string, args ...interface{}) {
+	l.log.Sugar().Fatalf(format, args...)
+}
+
+// V reports whether verbosity level level is enabled for the wrapped zap
+// core; level 0 maps to INFO, anything higher maps to DEBUG.
+func (l *Logger) V(level int) bool {
+	zl := zapcore.InfoLevel
+	if level > 0 {
+		zl = zapcore.DebugLevel
+	}
+	return l.log.Core().Enabled(zl)
+}
+
+// InfoKV implements grpclog.StructuredLoggerV2.
+func (l *Logger) InfoKV(msg string, kv ...interface{}) {
+	l.log.Sugar().Infow(msg, kv...)
+}
+
+// WarningKV implements grpclog.StructuredLoggerV2.
+func (l *Logger) WarningKV(msg string, kv ...interface{}) {
+	l.log.Sugar().Warnw(msg, kv...)
+}
+
+// ErrorKV implements grpclog.StructuredLoggerV2.
+func (l *Logger) ErrorKV(msg string, kv ...interface{}) {
+	l.log.Sugar().Errorw(msg, kv...)
+}
+
+// Log implements grpclog.StructuredLoggerV2.
+func (l *Logger) Log(severity grpclog.Severity, msg string, kv ...interface{}) {
+	switch severity {
+	case grpclog.SeverityWarning:
+		l.WarningKV(msg, kv...)
+	case grpclog.SeverityError:
+		l.ErrorKV(msg, kv...)
+	default:
+		l.InfoKV(msg, kv...)
+	}
+}
+
+// With implements grpclog.StructuredLoggerV2.
+func (l *Logger) With(kv ...interface{}) grpclog.StructuredLoggerV2 {
+	return &Logger{log: l.log.Sugar().With(kv...).Desugar()}
+}
+
+var _ grpclog.LoggerV2 = (*Logger)(nil)
+var _ grpclog.StructuredLoggerV2 = (*Logger)(nil)
@@ -26,6 +26,11 @@ import (
 
 var logger = grpclog.Component("channelz")
 
+// addTraceEventLogger is the single place that publishes a trace event to
+// SubscribeTraceEvents subscribers; only the *ToLogger helpers below go
+// through it. The non-ToLogger Info/Warning/Error family calls
+// AddTraceEvent directly, which does not publish, so their events are not
+// delivered to subscribers.
 func addTraceEventLogger(l grpclog.DepthLoggerV2, id int64, depth int, desc *TraceEventDesc) {
 	for d := desc; d != nil; d = d.Parent {
 		switch d.Severity {
@@ -39,25 +44,37 @@ func addTraceEventLogger(l grpclog.DepthLoggerV2, id int64, depth int, desc *Tra
 			l.ErrorDepth(depth+1, d.Desc)
 		}
 	}
+	publishTraceEvent(id, desc)
 	if getMaxTraceEntry() == 0 {
 		return
 	}
 	db.get().traceEvent(id, desc)
 }
 
-// Info logs and adds a trace event if channelz is on.
+// Info logs and adds a trace event if channelz is on. When channelz
+// is off, key/value fields (currently just id) are preserved through to
+// the logger whenever it supports StructuredLoggerV2; when channelz is
+// on, the trace event is the only emission, to avoid logging the same
+// line twice.
 func Info(id int64, args ...interface{}) {
+	msg := fmt.Sprint(args...)
 	if IsOn() {
 		AddTraceEvent(id, 1, &TraceEventDesc{
-			Desc:     fmt.Sprint(args...),
+			Desc:     msg,
 			Severity: CtINFO,
 		})
-	} else {
-		logger.InfoDepth(1, args...)
+		return
+	}
+	if sl, ok := logger.(grpclog.StructuredLoggerV2); ok {
+		sl.With("id", id).InfoKV(msg)
 	}
 }
 
-// Infof logs and adds a trace event if channelz is on.
+// Infof logs and adds a trace event if channelz is on. When channelz
+// is off, key/value fields (currently just id) are preserved through to
+// the logger whenever it supports StructuredLoggerV2; when channelz is
+// on, the trace event is the only emission, to avoid logging the same
+// line twice.
 func Infof(id int64, format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
 	if IsOn() {
@@ -65,8 +82,10 @@ func Infof(id int64, format string, args ...interface{}) {
 			Desc:     msg,
 			Severity: CtINFO,
 		})
-	} else {
-		logger.InfoDepth(1, msg)
+		return
+	}
+	if sl, ok := logger.(grpclog.StructuredLoggerV2); ok {
+		sl.With("id", id).InfoKV(msg)
 	}
 }
 
@@ -95,19 +114,30 @@ func InfofToLogger(l grpclog.DepthLoggerV2, id int64, format string, args ...int
 	}
 }
 
-// Warning logs and adds a trace event if channelz is on.
+// Warning logs and adds a trace event if channelz is on. When channelz
+// is off, key/value fields (currently just id) are preserved through to
+// the logger whenever it supports StructuredLoggerV2; when channelz is
+// on, the trace event is the only emission, to avoid logging the same
+// line twice.
 func Warning(id int64, args ...interface{}) {
+	msg := fmt.Sprint(args...)
 	if IsOn() {
 		AddTraceEvent(id, 1, &TraceEventDesc{
-			Desc:     fmt.Sprint(args...),
+			Desc:     msg,
 			Severity: CtWarning,
 		})
-	} else {
-		logger.WarningDepth(1, args...)
+		return
+	}
+	if sl, ok := logger.(grpclog.StructuredLoggerV2); ok {
+		sl.With("id", id).WarningKV(msg)
 	}
 }
 
-// Warningf log and adds a trace event if channelz is on.
+// Warningf logs and adds a trace event if channelz is on. When channelz
+// is off, key/value fields (currently just id) are preserved through to
+// the logger whenever it supports StructuredLoggerV2; when channelz is
+// on, the trace event is the only emission, to avoid logging the same
+// line twice.
 func Warningf(id int64, format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
 	if IsOn() {
@@ -115,8 +145,10 @@ func Warningf(id int64, format string, args ...interface{}) {
 			Desc:     msg,
 			Severity: CtWarning,
 		})
-	} else {
-		logger.WarningDepth(1, msg)
+		return
+	}
+	if sl, ok := logger.(grpclog.StructuredLoggerV2); ok {
+		sl.With("id", id).WarningKV(msg)
 	}
 }
 
@@ -145,19 +177,30 @@ func WarningfToLogger(l grpclog.DepthLoggerV2, id int64, format string, args ...
 	}
 }
 
-// Error logs and adds a trace event if channelz is on.
+// Error logs and adds a trace event if channelz is on. When channelz
+// is off, key/value fields (currently just id) are preserved through to
+// the logger whenever it supports StructuredLoggerV2; when channelz is
+// on, the trace event is the only emission, to avoid logging the same
+// line twice.
 func Error(id int64, args ...interface{}) {
+	msg := fmt.Sprint(args...)
 	if IsOn() {
 		AddTraceEvent(id, 1, &TraceEventDesc{
-			Desc:     fmt.Sprint(args...),
+			Desc:     msg,
 			Severity: CtError,
 		})
-	} else {
-		logger.ErrorDepth(1, args...)
+		return
+	}
+	if sl, ok := logger.(grpclog.StructuredLoggerV2); ok {
+		sl.With("id", id).ErrorKV(msg)
 	}
 }
 
-// Errorf logs and adds a trace event if channelz is on.
+// Errorf logs and adds a trace event if channelz is on. When channelz
+// is off, key/value fields (currently just id) are preserved through to
+// the logger whenever it supports StructuredLoggerV2; when channelz is
+// on, the trace event is the only emission, to avoid logging the same
+// line twice.
 func Errorf(id int64, format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
 	if IsOn() {
@@ -165,8 +208,10 @@ func Errorf(id int64, format string, args ...interface{}) {
 			Desc:     msg,
 			Severity: CtError,
 		})
-	} else {
-		logger.ErrorDepth(1, msg)
+		return
+	}
+	if sl, ok := logger.(grpclog.StructuredLoggerV2); ok {
+		sl.With("id", id).ErrorKV(msg)
 	}
 }
 
@@ -0,0 +1,156 @@
+/*
+ *
+ * Copyright 2020 gRPC authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package channelz
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// subscriberBufferSize bounds how many trace events a single subscriber can
+// lag behind by before events start being dropped for it.
+const subscriberBufferSize = 32
+
+// traceEventDelivery is what a subscriber's channel actually carries;
+// subscribers see it unpacked as (id, ev) via their callback.
+type traceEventDelivery struct {
+	id int64
+	ev TraceEventDesc
+}
+
+// traceEventSubscriber is a single SubscribeTraceEvents registration: a
+// bounded channel plus a count of events dropped for it because it fell
+// behind (drop-oldest, so a slow consumer never stalls the gRPC data path).
+type traceEventSubscriber struct {
+	ch      chan traceEventDelivery
+	dropped uint64 // atomic
+}
+
+var (
+	subscribersMu sync.Mutex
+	subscribers   = map[*traceEventSubscriber]struct{}{}
+)
+
+// SubscribeTraceEvents registers fn to be invoked, from a dedicated
+// goroutine, with every channelz trace event recorded through the
+// *ToLogger helpers (InfoToLogger, WarningfToLogger, ...), regardless of
+// whether channelz's own in-memory ring is enabled. Events recorded through
+// AddTraceEvent, including those of the non-ToLogger Info/Warning/Error
+// family, are not delivered. The parent chain of
+// each event (see TraceEventDesc.Parent) is flattened into a single
+// TraceEventDesc before delivery. The returned cancel func stops delivery
+// and may be called more than once.
+//
+// This lets external processes (e.g. a Prometheus or OpenTelemetry
+// exporter) stream channelz activity without polling the channelz gRPC
+// service.
+func SubscribeTraceEvents(fn func(id int64, ev TraceEventDesc)) (cancel func()) {
+	sub := &traceEventSubscriber{ch: make(chan traceEventDelivery, subscriberBufferSize)}
+	subscribersMu.Lock()
+	subscribers[sub] = struct{}{}
+	subscribersMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case d := <-sub.ch:
+				fn(d.id, d.ev)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			subscribersMu.Lock()
+			delete(subscribers, sub)
+			subscribersMu.Unlock()
+			close(done)
+		})
+	}
+}
+
+// DroppedTraceEvents is the dropped-count metric for SubscribeTraceEvents:
+// it returns the total number of trace events dropped across all current
+// subscribers because they fell behind. A Prometheus or OpenTelemetry
+// exporter built on SubscribeTraceEvents should poll this alongside its own
+// subscription to detect when it (or another subscriber) is falling behind
+// and dropping events, the same way it would poll any other counter.
+func DroppedTraceEvents() uint64 {
+	subscribersMu.Lock()
+	defer subscribersMu.Unlock()
+	var total uint64
+	for s := range subscribers {
+		total += atomic.LoadUint64(&s.dropped)
+	}
+	return total
+}
+
+// flattenTraceEvent concatenates a TraceEventDesc's parent chain into a
+// single description, so subscribers don't each have to walk it.
+func flattenTraceEvent(desc *TraceEventDesc) TraceEventDesc {
+	var sb strings.Builder
+	for d := desc; d != nil; d = d.Parent {
+		if sb.Len() > 0 {
+			sb.WriteString("; ")
+		}
+		sb.WriteString(d.Desc)
+	}
+	return TraceEventDesc{Desc: sb.String(), Severity: desc.Severity}
+}
+
+// publishTraceEvent fans desc out to every current subscriber. A
+// subscriber whose buffer is full has its oldest buffered event dropped
+// (and counted) to make room, rather than blocking the caller, which may be
+// on the RPC data path.
+func publishTraceEvent(id int64, desc *TraceEventDesc) {
+	subscribersMu.Lock()
+	if len(subscribers) == 0 {
+		subscribersMu.Unlock()
+		return
+	}
+	subs := make([]*traceEventSubscriber, 0, len(subscribers))
+	for s := range subscribers {
+		subs = append(subs, s)
+	}
+	subscribersMu.Unlock()
+
+	d := traceEventDelivery{id: id, ev: flattenTraceEvent(desc)}
+	for _, s := range subs {
+		select {
+		case s.ch <- d:
+			continue
+		default:
+		}
+		select {
+		case <-s.ch:
+			atomic.AddUint64(&s.dropped, 1)
+		default:
+		}
+		select {
+		case s.ch <- d:
+		default:
+			atomic.AddUint64(&s.dropped, 1)
+		}
+	}
+}
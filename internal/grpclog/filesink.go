@@ -0,0 +1,209 @@
+/*
+ *
+ * Copyright 2020 gRPC authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package grpclog
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Environment variables controlling the built-in rotating-file sink. When
+// logDirEnvName is set, a FileSink is registered automatically at init
+// time, mirroring glog's -log_dir flag.
+const (
+	logDirEnvName      = "GRPC_GO_LOG_DIR"
+	logMaxSizeEnvName  = "GRPC_GO_LOG_MAX_SIZE"
+	logMaxFilesEnvName = "GRPC_GO_LOG_MAX_FILES"
+
+	defaultMaxSize  = 100 << 20 // 100MB, matches glog's default.
+	defaultMaxFiles = 10
+)
+
+func init() {
+	dir, ok := os.LookupEnv(logDirEnvName)
+	if !ok || dir == "" {
+		return
+	}
+	fs, err := NewFileSink(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "grpclog: could not create %v file sink in %q: %v\n", logDirEnvName, dir, err)
+		return
+	}
+	RegisterSink(fs)
+}
+
+var severityNames = [...]string{"INFO", "WARNING", "ERROR", "FATAL"}
+
+// StderrSink is the LogSink registered by default: it forwards to the
+// installed Logger/DepthLogger, preserving gRPC's historical behavior of
+// logging to stderr.
+type StderrSink struct{}
+
+// Emit implements LogSink. e.Depth is relative to emit's own caller; two
+// more frames (emit itself, then this method) sit between that caller and
+// here, so e.Depth+2 is what gets passed down to DepthLogger. Args is
+// forwarded unflattened so a DepthLogger that does its own formatting
+// doesn't just see a pre-rendered string. Fatal-severity entries are
+// logged at Error level here rather than exiting: emit is the sole place
+// that calls os.Exit, once every sink (including one registered after this
+// one) has observed the entry.
+func (StderrSink) Emit(e LogEntry) {
+	switch e.Severity {
+	case SeverityInfo:
+		if DepthLogger != nil {
+			DepthLogger.InfoDepth(e.Depth+2, e.Args...)
+		} else {
+			Logger.Info(e.Args...)
+		}
+	case SeverityWarning:
+		if DepthLogger != nil {
+			DepthLogger.WarningDepth(e.Depth+2, e.Args...)
+		} else {
+			Logger.Warning(e.Args...)
+		}
+	case SeverityError, SeverityFatal:
+		if DepthLogger != nil {
+			DepthLogger.ErrorDepth(e.Depth+2, e.Args...)
+		} else {
+			Logger.Error(e.Args...)
+		}
+	}
+}
+
+// rotatingFile is a single open log file plus the byte count written to it
+// so FileSink knows when to rotate.
+type rotatingFile struct {
+	f    *os.File
+	size int64
+}
+
+// FileSink is a LogSink that writes one rotating file per severity into a
+// directory, using glog's file-naming and rotation scheme: files are named
+// "program.host.user.log.SEVERITY.YYYYMMDD-HHMMSS.pid" and a symlink
+// "program.SEVERITY" always points at the latest file for that severity.
+type FileSink struct {
+	dir      string
+	program  string
+	host     string
+	user     string
+	pid      int
+	maxSize  int64
+	maxFiles int
+
+	mu    sync.Mutex
+	files [len(severityNames)]*rotatingFile
+}
+
+// NewFileSink creates a FileSink rooted at dir, reading GRPC_GO_LOG_MAX_SIZE
+// (bytes) and GRPC_GO_LOG_MAX_FILES (count, per severity) from the
+// environment, falling back to glog-like defaults when unset or invalid.
+func NewFileSink(dir string) (*FileSink, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	host, _ := os.Hostname()
+	userName := "unknown"
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		userName = u.Username
+	}
+	program := filepath.Base(os.Args[0])
+
+	maxSize := int64(defaultMaxSize)
+	if v, ok := os.LookupEnv(logMaxSizeEnvName); ok {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil && n > 0 {
+			maxSize = n
+		}
+	}
+	maxFiles := defaultMaxFiles
+	if v, ok := os.LookupEnv(logMaxFilesEnvName); ok {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			maxFiles = n
+		}
+	}
+	return &FileSink{
+		dir:      dir,
+		program:  program,
+		host:     host,
+		user:     userName,
+		pid:      os.Getpid(),
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+	}, nil
+}
+
+// Emit implements LogSink.
+func (fs *FileSink) Emit(e LogEntry) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	rf, err := fs.fileFor(e.Severity, e.Timestamp)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "grpclog: file sink: %v\n", err)
+		return
+	}
+	n, _ := fmt.Fprintf(rf.f, "%c%s %d %s:%d] %s\n",
+		severityNames[e.Severity][0], e.Timestamp.Format("0102 15:04:05.000000"),
+		fs.pid, filepath.Base(e.File), e.Line, e.Message)
+	rf.size += int64(n)
+	if rf.size >= fs.maxSize {
+		fs.files[e.Severity] = nil
+		rf.f.Close()
+	}
+}
+
+// fileFor returns the currently open file for severity, opening (and
+// rotating into) a new one if none is open yet.
+func (fs *FileSink) fileFor(severity Severity, now time.Time) (*rotatingFile, error) {
+	if rf := fs.files[severity]; rf != nil {
+		return rf, nil
+	}
+	name := fmt.Sprintf("%s.%s.%s.log.%s.%s.%d",
+		fs.program, fs.host, fs.user, severityNames[severity], now.Format("20060102-150405"), fs.pid)
+	path := filepath.Join(fs.dir, name)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	link := filepath.Join(fs.dir, fs.program+"."+severityNames[severity])
+	os.Remove(link)
+	os.Symlink(name, link)
+	fs.pruneOldFiles(severity)
+	rf := &rotatingFile{f: f}
+	fs.files[severity] = rf
+	return rf, nil
+}
+
+// pruneOldFiles removes the oldest files for severity beyond maxFiles,
+// keeping disk usage bounded under long-running, high-rotation workloads.
+func (fs *FileSink) pruneOldFiles(severity Severity) {
+	pattern := filepath.Join(fs.dir, fmt.Sprintf("%s.%s.%s.log.%s.*", fs.program, fs.host, fs.user, severityNames[severity]))
+	matches, err := filepath.Glob(pattern)
+	if err != nil || len(matches) <= fs.maxFiles {
+		return
+	}
+	sort.Strings(matches)
+	for _, old := range matches[:len(matches)-fs.maxFiles] {
+		os.Remove(old)
+	}
+}
@@ -99,40 +99,32 @@ func CError(c Component, d int, args ...interface{}) {
 	}
 }
 
-// InfoDepth logs to the INFO log at the specified depth.
+// InfoDepth logs to the INFO log at the specified depth. It is routed
+// through every registered LogSink (see RegisterSink) rather than directly
+// through Logger/DepthLogger.
 func InfoDepth(depth int, args ...interface{}) {
-	if DepthLogger != nil {
-		DepthLogger.InfoDepth(depth, args...)
-	} else {
-		Logger.Info(args...)
-	}
+	emit(SeverityInfo, depth, args)
 }
 
-// WarningDepth logs to the WARNING log at the specified depth.
+// WarningDepth logs to the WARNING log at the specified depth. It is
+// routed through every registered LogSink (see RegisterSink) rather than
+// directly through Logger/DepthLogger.
 func WarningDepth(depth int, args ...interface{}) {
-	if DepthLogger != nil {
-		DepthLogger.WarningDepth(depth, args...)
-	} else {
-		Logger.Warning(args...)
-	}
+	emit(SeverityWarning, depth, args)
 }
 
-// ErrorDepth logs to the ERROR log at the specified depth.
+// ErrorDepth logs to the ERROR log at the specified depth. It is routed
+// through every registered LogSink (see RegisterSink) rather than directly
+// through Logger/DepthLogger.
 func ErrorDepth(depth int, args ...interface{}) {
-	if DepthLogger != nil {
-		DepthLogger.ErrorDepth(depth, args...)
-	} else {
-		Logger.Error(args...)
-	}
+	emit(SeverityError, depth, args)
 }
 
-// FatalDepth logs to the FATAL log at the specified depth.
+// FatalDepth logs to the FATAL log at the specified depth. It is routed
+// through every registered LogSink (see RegisterSink) rather than directly
+// through Logger/DepthLogger.
 func FatalDepth(depth int, args ...interface{}) {
-	if DepthLogger != nil {
-		DepthLogger.FatalDepth(depth, args...)
-	} else {
-		Logger.Fatal(args...)
-	}
+	emit(SeverityFatal, depth, args)
 }
 
 // LoggerV2 does underlying logging work for grpclog.
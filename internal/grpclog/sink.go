@@ -0,0 +1,123 @@
+/*
+ *
+ * Copyright 2020 gRPC authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package grpclog
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Severity identifies the severity of a LogEntry.
+type Severity int
+
+// Severity values for LogEntry.
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+	SeverityFatal
+)
+
+// LogEntry is a single log record forwarded to every registered LogSink.
+type LogEntry struct {
+	Severity  Severity
+	Timestamp time.Time
+	File      string
+	Line      int
+	Message   string
+	// Args are the original, unflattened arguments passed to InfoDepth/
+	// WarningDepth/ErrorDepth/FatalDepth, preserved alongside Message for
+	// sinks (such as the default one) that forward to a DepthLoggerV2 of
+	// their own and would otherwise lose that logger's own formatting.
+	Args []interface{}
+	// Depth is the depth originally passed to InfoDepth/WarningDepth/
+	// ErrorDepth/FatalDepth, preserved for sinks (such as the default one)
+	// that forward to a DepthLoggerV2 of their own. Such a sink must add
+	// its own frames between emit's caller and its forwarding call to
+	// DepthLogger when computing the depth it passes along.
+	Depth int
+}
+
+// LogSink receives every log entry produced through InfoDepth, WarningDepth,
+// ErrorDepth and FatalDepth.
+type LogSink interface {
+	Emit(entry LogEntry)
+}
+
+var (
+	sinksMu sync.Mutex
+	sinks   = []LogSink{StderrSink{}}
+)
+
+// RegisterSink adds s to the set of sinks that future log entries are
+// fanned out to. It is safe to call concurrently with logging.
+func RegisterSink(s LogSink) {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	sinks = append(sinks, s)
+}
+
+// UnregisterSink removes s, previously added with RegisterSink. It is a
+// no-op if s is not currently registered.
+func UnregisterSink(s LogSink) {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	for i, existing := range sinks {
+		if existing == s {
+			sinks = append(sinks[:i:i], sinks[i+1:]...)
+			return
+		}
+	}
+}
+
+// emit builds a LogEntry for the call site depth frames above its caller's
+// caller (depth 0 being whoever called InfoDepth etc.) and fans it
+// out to every registered sink. It is the sole place that exits the
+// process for a Fatal entry, once every sink (including a FileSink
+// registered after StderrSink) has had a chance to observe it; individual
+// sinks must not exit on their own.
+func emit(severity Severity, depth int, args []interface{}) {
+	_, file, line, _ := runtime.Caller(depth + 2)
+	entry := LogEntry{
+		Severity:  severity,
+		Timestamp: time.Now(),
+		File:      file,
+		Line:      line,
+		Message:   fmt.Sprint(args...),
+		Args:      args,
+		Depth:     depth,
+	}
+	sinksMu.Lock()
+	snapshot := make([]LogSink, len(sinks))
+	copy(snapshot, sinks)
+	sinksMu.Unlock()
+	for _, s := range snapshot {
+		s.Emit(entry)
+	}
+	if severity == SeverityFatal {
+		os.Exit(1)
+	}
+}
+
+// StderrSink (defined in filesink.go) is registered by default above,
+// preserving the pre-existing behavior of forwarding to DepthLogger (or
+// Logger, if no DepthLogger is installed).
@@ -0,0 +1,273 @@
+/*
+ *
+ * Copyright 2020 gRPC authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// adaptiveWindow is how far back EndRequestWithStatus samples are kept for
+// computing the p95 latency and error rate used to drive the adaptive mode,
+// bucketed into one-second slots.
+const (
+	adaptiveWindow     = 30 * time.Second
+	adaptiveBucketSize = time.Second
+	adaptiveBuckets    = int(adaptiveWindow / adaptiveBucketSize)
+)
+
+// latencyHistogramBuckets is the number of exponentially-sized latency
+// buckets each requestBucket keeps, so recordSampleLocked and
+// windowStatsLocked are O(1) per call instead of storing every raw sample
+// and sorting the whole window on every request.
+const (
+	latencyHistogramBuckets = 16
+	latencyHistogramBase    = time.Millisecond
+)
+
+// latencyBucketUpperBound returns the exclusive upper bound of latency
+// bucket i: bucket 0 is [0, 2ms), bucket 1 is [2ms, 4ms), doubling up
+// through the last bucket, which is open-ended above ~32.8s and reports its
+// own lower bound as the estimate for anything that falls in it.
+func latencyBucketUpperBound(i int) time.Duration {
+	if i >= latencyHistogramBuckets-1 {
+		return latencyHistogramBase << uint(latencyHistogramBuckets-1)
+	}
+	return latencyHistogramBase << uint(i+1)
+}
+
+// latencyBucketIndex returns the latency histogram bucket d falls into.
+func latencyBucketIndex(d time.Duration) int {
+	for i := 0; i < latencyHistogramBuckets-1; i++ {
+		if d < latencyBucketUpperBound(i) {
+			return i
+		}
+	}
+	return latencyHistogramBuckets - 1
+}
+
+// AdaptiveConfig configures the adaptive (outlier-aware) concurrency mode
+// of a ServiceRequestsCounter: maxRequests is additively increased
+// (IncreaseStep) while the service looks healthy, and multiplicatively
+// decreased (DecreaseFactor) toward MinRequests as soon as the rolling p95
+// latency or error rate crosses its target, the same AIMD behavior as a
+// TCP congestion window.
+type AdaptiveConfig struct {
+	// MinRequests is the floor maxRequests is decreased toward.
+	MinRequests uint32
+	// MaxRequests is the ceiling maxRequests is increased toward.
+	MaxRequests uint32
+	// LatencyTarget is the p95 latency, over the trailing 30s, above which
+	// the service is considered unhealthy. Zero disables the latency
+	// trigger.
+	LatencyTarget time.Duration
+	// ErrorRateTarget is the error rate, over the trailing 30s, above which
+	// the service is considered unhealthy. Zero disables the error-rate
+	// trigger.
+	ErrorRateTarget float64
+	// DecreaseFactor multiplies maxRequests when unhealthy; a value outside
+	// (0, 1), including zero, is replaced by 0.5.
+	DecreaseFactor float64
+	// IncreaseStep is added to maxRequests once per EndRequestWithStatus
+	// call while healthy.
+	IncreaseStep uint32
+}
+
+// requestBucket accumulates a latency histogram and error count for a
+// single one-second slot of the adaptive window.
+type requestBucket struct {
+	sec         int64
+	latencyHist [latencyHistogramBuckets]uint32
+	total       uint32
+	errors      uint32
+}
+
+// ServiceRequestsCounter is used to track the number of active requests for
+// a service, enforcing a circuit-breaking maxRequests cap. Construct it
+// directly (e.g. ServiceRequestsCounter{ServiceName: name}); it starts with
+// no cap until UpdateCounter or UpdateAdaptiveConfig is called.
+type ServiceRequestsCounter struct {
+	ServiceName string
+
+	mu          sync.Mutex
+	maxRequests *uint32
+	numRequests uint32
+
+	adaptive *AdaptiveConfig
+	buckets  [adaptiveBuckets]requestBucket
+}
+
+// StartRequest starts a request for a service, incrementing its number of
+// requests. If the number of requests has reached the configured maximum,
+// it returns a non-nil error and does not start the request.
+func (c *ServiceRequestsCounter) StartRequest() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.maxRequests != nil && c.numRequests >= *c.maxRequests {
+		return fmt.Errorf("max requests %v exceeded on service %v", *c.maxRequests, c.ServiceName)
+	}
+	c.numRequests++
+	return nil
+}
+
+// EndRequest ends a request for a service, decrementing its number of
+// requests. Use EndRequestWithStatus instead when adaptive mode is in use.
+func (c *ServiceRequestsCounter) EndRequest() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.endRequestLocked()
+}
+
+// EndRequestWithStatus ends a request for a service like EndRequest, and
+// additionally feeds err and latency into the adaptive window and applies
+// one AIMD adjustment of maxRequests based on it, within this same call. It
+// is a no-op beyond EndRequest's bookkeeping when adaptive mode has not
+// been configured via UpdateAdaptiveConfig.
+func (c *ServiceRequestsCounter) EndRequestWithStatus(err error, latency time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if rerr := c.endRequestLocked(); rerr != nil {
+		return rerr
+	}
+	if c.adaptive != nil {
+		c.recordSampleLocked(err, latency)
+		c.adjustMaxRequestsLocked()
+	}
+	return nil
+}
+
+func (c *ServiceRequestsCounter) endRequestLocked() error {
+	if c.numRequests == 0 {
+		return fmt.Errorf("no requests active for service %v, trying to end one", c.ServiceName)
+	}
+	c.numRequests--
+	return nil
+}
+
+// UpdateCounter updates the maxRequests cap for a service. A nil max
+// removes the cap entirely (StartRequest never rejects). This disables
+// adaptive mode; use UpdateAdaptiveConfig to re-enable it.
+func (c *ServiceRequestsCounter) UpdateCounter(max *uint32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxRequests = max
+	c.adaptive = nil
+}
+
+// UpdateAdaptiveConfig enables adaptive mode: maxRequests is clamped into
+// [cfg.MinRequests, cfg.MaxRequests] and from then on is driven by
+// EndRequestWithStatus samples rather than UpdateCounter.
+func (c *ServiceRequestsCounter) UpdateAdaptiveConfig(cfg AdaptiveConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cfg.DecreaseFactor <= 0 || cfg.DecreaseFactor >= 1 {
+		cfg.DecreaseFactor = 0.5
+	}
+	c.adaptive = &cfg
+	max := cfg.MaxRequests
+	if c.maxRequests == nil || *c.maxRequests > cfg.MaxRequests {
+		c.maxRequests = &max
+	}
+	if *c.maxRequests < cfg.MinRequests {
+		min := cfg.MinRequests
+		c.maxRequests = &min
+	}
+}
+
+// recordSampleLocked records a single request's outcome into the current
+// one-second bucket of the adaptive window, resetting stale buckets (those
+// left over from a prior pass through the ring) as it goes.
+func (c *ServiceRequestsCounter) recordSampleLocked(err error, latency time.Duration) {
+	sec := time.Now().Unix()
+	b := &c.buckets[int(sec%int64(adaptiveBuckets))]
+	if b.sec != sec {
+		b.sec = sec
+		b.latencyHist = [latencyHistogramBuckets]uint32{}
+		b.total = 0
+		b.errors = 0
+	}
+	b.latencyHist[latencyBucketIndex(latency)]++
+	b.total++
+	if err != nil {
+		b.errors++
+	}
+}
+
+// windowStatsLocked returns the p95 latency (estimated from the merged
+// latency histograms of every bucket still within adaptiveWindow of now,
+// to bound the cost of this per-request call regardless of QPS) and the
+// error rate over that same window.
+func (c *ServiceRequestsCounter) windowStatsLocked() (p95 time.Duration, errorRate float64) {
+	now := time.Now().Unix()
+	var hist [latencyHistogramBuckets]uint32
+	var sampled, total, errs uint32
+	for i := range c.buckets {
+		b := &c.buckets[i]
+		if b.sec == 0 || now-b.sec >= int64(adaptiveBuckets) {
+			continue
+		}
+		for j, n := range b.latencyHist {
+			hist[j] += n
+			sampled += n
+		}
+		total += b.total
+		errs += b.errors
+	}
+	if sampled > 0 {
+		target := uint32(float64(sampled) * 0.95)
+		var cum uint32
+		for i, n := range hist {
+			cum += n
+			if cum > target {
+				p95 = latencyBucketUpperBound(i)
+				break
+			}
+		}
+	}
+	if total > 0 {
+		errorRate = float64(errs) / float64(total)
+	}
+	return p95, errorRate
+}
+
+// adjustMaxRequestsLocked applies one AIMD step: a multiplicative decrease
+// toward MinRequests when the service looks unhealthy, otherwise an
+// additive increase toward MaxRequests.
+func (c *ServiceRequestsCounter) adjustMaxRequestsLocked() {
+	cfg := c.adaptive
+	p95, errRate := c.windowStatsLocked()
+	unhealthy := (cfg.LatencyTarget > 0 && p95 > cfg.LatencyTarget) ||
+		(cfg.ErrorRateTarget > 0 && errRate > cfg.ErrorRateTarget)
+
+	cur := *c.maxRequests
+	var next uint32
+	if unhealthy {
+		next = uint32(float64(cur) * cfg.DecreaseFactor)
+		if next < cfg.MinRequests {
+			next = cfg.MinRequests
+		}
+	} else {
+		next = cur + cfg.IncreaseStep
+		if next > cfg.MaxRequests {
+			next = cfg.MaxRequests
+		}
+	}
+	c.maxRequests = &next
+}